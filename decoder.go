@@ -0,0 +1,190 @@
+// Copyright 2013 Silas Snider. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package stl
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math"
+)
+
+const (
+	binaryHeaderSize = 80
+	binaryRecordSize = 50
+	solidLen         = len("solid")
+)
+
+//Decoder reads a stream of Facets from an STL file, one at a time, without
+//requiring the whole file to be resident in memory. NewDecoder auto-detects
+//ASCII vs. binary before returning the Decoder.
+type Decoder struct {
+	r        *bufio.Reader
+	isBinary bool
+	header   [binaryHeaderSize]byte
+	meshName string
+
+	// binary decoding state.
+	numTriangles uint32
+	read         uint32
+
+	// ascii decoding state. The existing lexer works over an in-memory
+	// []byte, so ascii input (all of it, across every solid block) is
+	// buffered once on NewDecoder; the win for streaming is on the binary
+	// path, which is the hot spot in practice.
+	solids   []Solid
+	solidIdx int
+	facetIdx int
+}
+
+// NewDecoder returns a Decoder that reads Facets from r.
+func NewDecoder(r io.Reader) (*Decoder, error) {
+	d := &Decoder{r: bufio.NewReaderSize(r, 64*1024)}
+	if err := d.sniff(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// sniff decides between ASCII and binary. A binary file's 80-byte header
+// may itself start with "solid" (see TestNonStandardBinaryParse), and a
+// long mesh name can push the ASCII "facet" keyword arbitrarily far into
+// the stream, so peeking a fixed window isn't enough to tell them apart;
+// instead, buffer the rest of the stream and attempt the same real
+// trial-parse ParseSolids uses.
+func (d *Decoder) sniff() error {
+	head, err := d.r.Peek(solidLen)
+	if err != nil && err != io.EOF {
+		return err
+	}
+	if len(head) < solidLen || string(head) != "solid" {
+		return d.startBinary()
+	}
+	data, err := ioutil.ReadAll(d.r)
+	if err != nil {
+		return err
+	}
+	return d.startASCII(data)
+}
+
+func (d *Decoder) startASCII(data []byte) error {
+	solids, err := scanASCIISolids(data[solidLen:])
+	if err != nil {
+		if len(solids) > 0 {
+			// At least one solid block parsed cleanly, so this is a real
+			// ASCII syntax error, not a binary misdetection.
+			return err
+		}
+		return d.startBinaryFromBuffer(data)
+	}
+	d.solids = solids
+	if len(solids) > 0 {
+		d.meshName = solids[0].Name
+	}
+	return nil
+}
+
+func (d *Decoder) startBinary() error {
+	d.isBinary = true
+	if _, err := io.ReadFull(d.r, d.header[:]); err != nil {
+		return errors.New("Incomplete header on binary STL file.")
+	}
+	var countBuf [4]byte
+	if _, err := io.ReadFull(d.r, countBuf[:]); err != nil {
+		return errors.New("Binary STL file contains no data.")
+	}
+	d.numTriangles = binary.LittleEndian.Uint32(countBuf[:])
+	return nil
+}
+
+// startBinaryFromBuffer is startBinary's counterpart for the case where
+// the stream has already been fully buffered while attempting (and
+// failing) an ASCII trial-parse in startASCII.
+func (d *Decoder) startBinaryFromBuffer(data []byte) error {
+	d.isBinary = true
+	if len(data) < binaryHeaderSize {
+		return errors.New("Incomplete header on binary STL file.")
+	}
+	copy(d.header[:], data[:binaryHeaderSize])
+	data = data[binaryHeaderSize:]
+	if len(data) < 4 {
+		return errors.New("Binary STL file contains no data.")
+	}
+	d.numTriangles = binary.LittleEndian.Uint32(data[:4])
+	d.r = bufio.NewReader(bytes.NewReader(data[4:]))
+	return nil
+}
+
+// Header returns the 80-byte binary header. It is the zero value when the
+// stream is ASCII.
+func (d *Decoder) Header() [binaryHeaderSize]byte {
+	return d.header
+}
+
+// MeshName returns the name following "solid" in an ASCII stream: the
+// first solid block's name, if the stream has more than one. It is
+// always empty for binary streams.
+func (d *Decoder) MeshName() string {
+	return d.meshName
+}
+
+// Decode returns the next Facet in the stream, or io.EOF once all facets
+// from every solid block have been read.
+func (d *Decoder) Decode() (*Facet, error) {
+	if d.isBinary {
+		return d.decodeBinary()
+	}
+	return d.decodeASCII()
+}
+
+func (d *Decoder) decodeASCII() (*Facet, error) {
+	for d.solidIdx < len(d.solids) {
+		facets := d.solids[d.solidIdx].Facets
+		if d.facetIdx < len(facets) {
+			f := facets[d.facetIdx]
+			d.facetIdx++
+			return f, nil
+		}
+		d.solidIdx++
+		d.facetIdx = 0
+	}
+	return nil, io.EOF
+}
+
+func (d *Decoder) decodeBinary() (*Facet, error) {
+	if d.read >= d.numTriangles {
+		return nil, io.EOF
+	}
+	var record [binaryRecordSize]byte
+	if _, err := io.ReadFull(d.r, record[:]); err != nil {
+		return nil, err
+	}
+	d.read++
+
+	f := &Facet{
+		Normal: decodeVertex(record[0:12]),
+		Vertices: [3]Vertex{
+			decodeVertex(record[12:24]),
+			decodeVertex(record[24:36]),
+			decodeVertex(record[36:48]),
+		},
+		Valid:     true,
+		Attribute: binary.LittleEndian.Uint16(record[48:50]),
+	}
+	return f, nil
+}
+
+// decodeVertex reads three little-endian float32s directly out of b,
+// rather than going through binary.Read on a *bytes.Buffer.
+func decodeVertex(b []byte) Vertex {
+	return Vertex{
+		X: math.Float32frombits(binary.LittleEndian.Uint32(b[0:4])),
+		Y: math.Float32frombits(binary.LittleEndian.Uint32(b[4:8])),
+		Z: math.Float32frombits(binary.LittleEndian.Uint32(b[8:12])),
+	}
+}
@@ -0,0 +1,232 @@
+// Copyright 2013 Silas Snider. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package stl
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func decodeAll(t *testing.T, r io.Reader) []*Facet {
+	t.Helper()
+	d, err := NewDecoder(r)
+	if err != nil {
+		t.Fatalf("NewDecoder: %v", err)
+	}
+	var facets []*Facet
+	for {
+		f, err := d.Decode()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Decode: %v", err)
+		}
+		facets = append(facets, f)
+	}
+	return facets
+}
+
+func TestDecoderASCIILongMeshName(t *testing.T) {
+	// A mesh name long enough to push "facet" well past any fixed peek
+	// window must still be recognized as ASCII, not misdetected as binary.
+	name := strings.Repeat("n", 200)
+	input := "solid " + name + `
+  facet normal 1 0 0
+    outer loop
+      vertex -2 0.5 -0.5
+      vertex -2 1.5 -0.5
+      vertex -2 1.5 0.5
+    endloop
+  endfacet
+endsolid ` + name
+	d, err := NewDecoder(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("NewDecoder: %v", err)
+	}
+	if d.MeshName() != name {
+		t.Fatalf("MeshName() = %q, expected %q", d.MeshName(), name)
+	}
+	facets := decodeAll(t, strings.NewReader(input))
+	if len(facets) != 1 {
+		t.Fatalf("Wrong number of facets: got %d, expected 1", len(facets))
+	}
+	tv := Vertex{X: -2, Y: 0.5, Z: -0.5}
+	if facets[0].Vertices[0] != tv {
+		t.Fatalf("Incorrect parse. Got %+v, expected %+v", facets[0].Vertices[0], tv)
+	}
+}
+
+func TestDecoderASCIIMultiSolid(t *testing.T) {
+	const input = `solid first
+  facet normal 1 0 0
+    outer loop
+      vertex -2 0.5 -0.5
+      vertex -2 1.5 -0.5
+      vertex -2 1.5 0.5
+    endloop
+  endfacet
+endsolid first
+solid second
+  facet normal 0 1 0
+    outer loop
+      vertex 0 0 0
+      vertex 1 0 0
+      vertex 0 1 0
+    endloop
+  endfacet
+endsolid second
+`
+	facets := decodeAll(t, strings.NewReader(input))
+	if len(facets) != 2 {
+		t.Fatalf("Wrong number of facets: got %d, expected 2", len(facets))
+	}
+}
+
+func TestDecoderASCIISecondBlockMalformed(t *testing.T) {
+	// The first block is well-formed ASCII; the second has a syntax error.
+	// Decode must surface the real parse error rather than silently
+	// falling back to a binary (re)interpretation of the stream.
+	const input = `solid first
+  facet normal 1 0 0
+    outer loop
+      vertex -2 0.5 -0.5
+      vertex -2 1.5 -0.5
+      vertex -2 1.5 0.5
+    endloop
+  endfacet
+endsolid first
+solid second
+  facet normal 0 1 0
+    outer loop
+      NOT_A_VERTEX_KEYWORD 0 0 0
+      vertex 1 0 0
+      vertex 0 1 0
+    endloop
+  endfacet
+endsolid second
+`
+	if _, err := NewDecoder(strings.NewReader(input)); err == nil {
+		t.Fatal("NewDecoder: expected an error, got nil")
+	}
+}
+
+func TestDecoderASCII(t *testing.T) {
+	const input = `solid OpenSCAD_Model
+  facet normal 1 0 0
+    outer loop
+      vertex -2 0.5 -0.5
+      vertex -2 1.5 -0.5
+      vertex -2 1.5 0.5
+    endloop
+  endfacet
+endsolid OpenSCAD_Model`
+	facets := decodeAll(t, strings.NewReader(input))
+	if len(facets) != 1 {
+		t.Fatalf("Wrong number of facets: got %d, expected 1", len(facets))
+	}
+	tv := Vertex{X: -2, Y: 0.5, Z: -0.5}
+	if facets[0].Vertices[0] != tv {
+		t.Fatalf("Incorrect parse. Got %+v, expected %+v", facets[0].Vertices[0], tv)
+	}
+}
+
+func TestDecoderBinary(t *testing.T) {
+	const input = "\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x02\x00\x00\x00\x00\x00\x80?\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\xc0\x00\x00\x00?\x00\x00\x00\xbf\x00\x00\x00\xc0\x00\x00\xc0?\x00\x00\x00\xbf\x00\x00\x00\xc0\x00\x00\xc0?\x00\x00\x00?\x00\x00\x00\x00\x80?\x00\x00\x00\x00\x00\x00\x00\x80\x00\x00\x00\xc0\x00\x00\x00?\x00\x00\x00?\x00\x00\x00\xc0\x00\x00\x00?\x00\x00\x00\xbf\x00\x00\x00\xc0\x00\x00\xc0?\x00\x00\x00?\x00\x00"
+	facets := decodeAll(t, strings.NewReader(input))
+	if len(facets) != 2 {
+		t.Fatalf("Wrong number of facets: got %d, expected 2", len(facets))
+	}
+	tv := Vertex{X: -2, Y: 0.5, Z: -0.5}
+	if facets[0].Vertices[0] != tv {
+		t.Fatalf("Incorrect parse. Got: %+v, expected %+v", facets[0].Vertices[0], tv)
+	}
+	tn := Vertex{X: 1, Y: 0, Z: 0}
+	if facets[0].Normal != tn {
+		t.Fatalf("Incorrect normal. Got %+v, expected %+v", facets[0].Normal, tn)
+	}
+}
+
+func TestDecoderNonStandardBinary(t *testing.T) {
+	const input = "solid\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x02\x00\x00\x00\x00\x00\x80?\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\xc0\x00\x00\x00?\x00\x00\x00\xbf\x00\x00\x00\xc0\x00\x00\xc0?\x00\x00\x00\xbf\x00\x00\x00\xc0\x00\x00\xc0?\x00\x00\x00?\x00\x00\x00\x00\x80?\x00\x00\x00\x00\x00\x00\x00\x80\x00\x00\x00\xc0\x00\x00\x00?\x00\x00\x00?\x00\x00\x00\xc0\x00\x00\x00?\x00\x00\x00\xbf\x00\x00\x00\xc0\x00\x00\xc0?\x00\x00\x00?\x00\x00"
+	facets := decodeAll(t, strings.NewReader(input))
+	if len(facets) != 2 {
+		t.Fatalf("Wrong number of facets: got %d, expected 2", len(facets))
+	}
+	tv := Vertex{X: -2, Y: 0.5, Z: -0.5}
+	if facets[0].Vertices[0] != tv {
+		t.Fatalf("Incorrect parse. Got: %+v, expected %+v", facets[0].Vertices[0], tv)
+	}
+}
+
+func TestEncoderASCIIRoundTrip(t *testing.T) {
+	facets := []*Facet{
+		{
+			Normal:   Vertex{X: 1, Y: 0, Z: 0},
+			Vertices: [3]Vertex{{X: -2, Y: 0.5, Z: -0.5}, {X: -2, Y: 1.5, Z: -0.5}, {X: -2, Y: 1.5, Z: 0.5}},
+			Valid:    true,
+		},
+	}
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf, ASCIIFormat, "roundtrip")
+	for _, f := range facets {
+		if err := enc.Encode(f); err != nil {
+			t.Fatalf("Encode: %v", err)
+		}
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	got, err := ParseSTLBytes(buf.Bytes())
+	if err != nil {
+		t.Fatalf("ParseSTLBytes: %v", err)
+	}
+	if len(got) != len(facets) {
+		t.Fatalf("Wrong number of facets: got %d, expected %d", len(got), len(facets))
+	}
+	if got[0].Vertices != facets[0].Vertices || got[0].Normal != facets[0].Normal {
+		t.Fatalf("Incorrect round trip. Got %+v, expected %+v", got[0], facets[0])
+	}
+}
+
+func TestEncoderBinaryRoundTrip(t *testing.T) {
+	facets := []*Facet{
+		{
+			Normal:   Vertex{X: 1, Y: 0, Z: 0},
+			Vertices: [3]Vertex{{X: -2, Y: 0.5, Z: -0.5}, {X: -2, Y: 1.5, Z: -0.5}, {X: -2, Y: 1.5, Z: 0.5}},
+			Valid:    true,
+		},
+		{
+			Normal:   Vertex{X: 0, Y: 1, Z: 0},
+			Vertices: [3]Vertex{{X: -2, Y: 0.5, Z: 0.5}, {X: -2, Y: 0.5, Z: -0.5}, {X: -2, Y: 1.5, Z: 0.5}},
+			Valid:    true,
+		},
+	}
+	// The binary encoder buffers every facet until Close, then writes the
+	// triangle count followed by the records.
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf, BinaryFormat, "roundtrip")
+	for _, f := range facets {
+		if err := enc.Encode(f); err != nil {
+			t.Fatalf("Encode: %v", err)
+		}
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	got := decodeAll(t, bytes.NewReader(buf.Bytes()))
+	if len(got) != len(facets) {
+		t.Fatalf("Wrong number of facets: got %d, expected %d", len(got), len(facets))
+	}
+	for i := range facets {
+		if got[i].Vertices != facets[i].Vertices || got[i].Normal != facets[i].Normal {
+			t.Fatalf("Incorrect round trip at facet %d. Got %+v, expected %+v", i, got[i], facets[i])
+		}
+	}
+}
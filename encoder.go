@@ -0,0 +1,153 @@
+// Copyright 2013 Silas Snider. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package stl
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+//EncoderFormat selects the on-disk representation an Encoder writes.
+type EncoderFormat int
+
+const (
+	// ASCIIFormat writes the human-readable solid/facet/endsolid format.
+	ASCIIFormat EncoderFormat = iota
+	// BinaryFormat writes the compact 50-byte-per-facet binary format.
+	BinaryFormat
+)
+
+//Encoder writes a stream of Facets to an STL file, either ASCII or binary.
+type Encoder struct {
+	w      *bufio.Writer
+	format EncoderFormat
+
+	meshName      string
+	headerWritten bool
+
+	count uint32
+	// pending buffers facets for binary mode, since the triangle count
+	// must be written before the facet data.
+	pending []*Facet
+	closed  bool
+}
+
+// NewEncoder returns an Encoder that writes Facets to w as an STL file
+// named meshName, in the given format.
+func NewEncoder(w io.Writer, format EncoderFormat, meshName string) *Encoder {
+	return &Encoder{
+		w:        bufio.NewWriter(w),
+		format:   format,
+		meshName: meshName,
+	}
+}
+
+// Encode writes a single Facet to the stream.
+func (e *Encoder) Encode(f *Facet) error {
+	if e.format == ASCIIFormat {
+		return e.encodeASCII(f)
+	}
+	return e.encodeBinary(f)
+}
+
+func (e *Encoder) encodeASCII(f *Facet) error {
+	if !e.headerWritten {
+		if _, err := fmt.Fprintf(e.w, "solid %s\n", e.meshName); err != nil {
+			return err
+		}
+		e.headerWritten = true
+	}
+	_, err := fmt.Fprintf(e.w,
+		"  facet normal %v %v %v\n    outer loop\n      vertex %v %v %v\n      vertex %v %v %v\n      vertex %v %v %v\n    endloop\n  endfacet\n",
+		f.Normal.X, f.Normal.Y, f.Normal.Z,
+		f.Vertices[0].X, f.Vertices[0].Y, f.Vertices[0].Z,
+		f.Vertices[1].X, f.Vertices[1].Y, f.Vertices[1].Z,
+		f.Vertices[2].X, f.Vertices[2].Y, f.Vertices[2].Z)
+	return err
+}
+
+func (e *Encoder) encodeBinary(f *Facet) error {
+	e.count++
+	e.pending = append(e.pending, f)
+	return nil
+}
+
+func (e *Encoder) writeBinaryHeader(count uint32) error {
+	var header [binaryHeaderSize]byte
+	copy(header[:], e.meshName)
+	if _, err := e.w.Write(header[:]); err != nil {
+		return err
+	}
+	var countBuf [4]byte
+	binary.LittleEndian.PutUint32(countBuf[:], count)
+	_, err := e.w.Write(countBuf[:])
+	return err
+}
+
+// writeBinaryRecord writes a 50-byte facet record directly with
+// binary.LittleEndian, rather than building it through binary.Write.
+func (e *Encoder) writeBinaryRecord(f *Facet) error {
+	_, err := e.w.Write(binaryRecordBytes(f))
+	return err
+}
+
+// binaryRecordBytes encodes f as the 50-byte record the binary format
+// uses: a normal, three vertices, and a 2-byte attribute byte count.
+func binaryRecordBytes(f *Facet) []byte {
+	var record [binaryRecordSize]byte
+	encodeVertex(record[0:12], f.Normal)
+	encodeVertex(record[12:24], f.Vertices[0])
+	encodeVertex(record[24:36], f.Vertices[1])
+	encodeVertex(record[36:48], f.Vertices[2])
+	binary.LittleEndian.PutUint16(record[48:50], f.Attribute)
+	return record[:]
+}
+
+func encodeVertex(b []byte, v Vertex) {
+	binary.LittleEndian.PutUint32(b[0:4], math.Float32bits(v.X))
+	binary.LittleEndian.PutUint32(b[4:8], math.Float32bits(v.Y))
+	binary.LittleEndian.PutUint32(b[8:12], math.Float32bits(v.Z))
+}
+
+// Close flushes any buffered output and, for binary mode, finalizes the
+// triangle count. It must be called once encoding is complete.
+func (e *Encoder) Close() error {
+	if e.closed {
+		return nil
+	}
+	e.closed = true
+	if e.format == ASCIIFormat {
+		return e.closeASCII()
+	}
+	return e.closeBinary()
+}
+
+func (e *Encoder) closeASCII() error {
+	if !e.headerWritten {
+		if _, err := fmt.Fprintf(e.w, "solid %s\n", e.meshName); err != nil {
+			return err
+		}
+		e.headerWritten = true
+	}
+	if _, err := fmt.Fprintf(e.w, "endsolid %s\n", e.meshName); err != nil {
+		return err
+	}
+	return e.w.Flush()
+}
+
+func (e *Encoder) closeBinary() error {
+	if err := e.writeBinaryHeader(e.count); err != nil {
+		return err
+	}
+	for _, f := range e.pending {
+		if err := e.writeBinaryRecord(f); err != nil {
+			return err
+		}
+	}
+	return e.w.Flush()
+}
@@ -0,0 +1,56 @@
+// Copyright 2013 Silas Snider. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package mesh turns the facet soup produced by package stl into an
+// indexed mesh with welded vertices, and provides normal recomputation
+// and validation for the result.
+package mesh
+
+import (
+	"math"
+
+	"github.com/SniderLabs/stl"
+)
+
+//Mesh is an indexed triangle mesh: every three consecutive Indices name a
+//triangle's corners into Vertices (and, once computed, Normals).
+type Mesh struct {
+	Vertices []stl.Vertex
+	Indices  []uint32
+	Normals  []stl.Vertex
+}
+
+// quantize maps a vertex onto an integer grid of the given cell size, so
+// that vertices in the same epsilon-sized cell hash to the same key. Two
+// vertices closer than epsilon that lie on opposite sides of a cell
+// boundary get different keys.
+func quantize(v stl.Vertex, epsilon float32) [3]int32 {
+	return [3]int32{
+		int32(math.Floor(float64(v.X / epsilon))),
+		int32(math.Floor(float64(v.Y / epsilon))),
+		int32(math.Floor(float64(v.Z / epsilon))),
+	}
+}
+
+// New builds an indexed Mesh from facets, welding vertices that quantize
+// to the same epsilon-sized grid cell via a spatial hash so that
+// coincident vertices collapse in O(n) instead of the O(n^2) an all-pairs
+// comparison would need. The first vertex seen in a cell is kept.
+func New(facets []*stl.Facet, epsilon float32) *Mesh {
+	m := &Mesh{}
+	seen := make(map[[3]int32]uint32, len(facets)*3)
+	for _, f := range facets {
+		for _, v := range f.Vertices {
+			key := quantize(v, epsilon)
+			idx, ok := seen[key]
+			if !ok {
+				idx = uint32(len(m.Vertices))
+				m.Vertices = append(m.Vertices, v)
+				seen[key] = idx
+			}
+			m.Indices = append(m.Indices, idx)
+		}
+	}
+	return m
+}
@@ -0,0 +1,146 @@
+// Copyright 2013 Silas Snider. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package mesh
+
+import (
+	"testing"
+
+	"github.com/SniderLabs/stl"
+)
+
+// quad returns two triangles sharing an edge, forming a unit square in the
+// z=0 plane, consistently wound counter-clockwise when viewed from +Z.
+func quad() []*stl.Facet {
+	a := stl.Vertex{X: 0, Y: 0, Z: 0}
+	b := stl.Vertex{X: 1, Y: 0, Z: 0}
+	c := stl.Vertex{X: 1, Y: 1, Z: 0}
+	d := stl.Vertex{X: 0, Y: 1, Z: 0}
+	return []*stl.Facet{
+		{Vertices: [3]stl.Vertex{a, b, c}, Valid: true},
+		{Vertices: [3]stl.Vertex{a, c, d}, Valid: true},
+	}
+}
+
+func TestNewWeldsSharedVertices(t *testing.T) {
+	m := New(quad(), 1e-4)
+	if len(m.Vertices) != 4 {
+		t.Fatalf("Expected 4 welded vertices, got %d", len(m.Vertices))
+	}
+	if len(m.Indices) != 6 {
+		t.Fatalf("Expected 6 indices, got %d", len(m.Indices))
+	}
+}
+
+func TestRecomputeNormalsFlat(t *testing.T) {
+	m := New(quad(), 1e-4)
+	RecomputeNormals(m, FlatNormals)
+	want := stl.Vertex{X: 0, Y: 0, Z: 1}
+	for i, n := range m.Normals {
+		if n != want {
+			t.Errorf("Normal %d = %+v, want %+v", i, n, want)
+		}
+	}
+}
+
+func TestRecomputeNormalsAngleWeighted(t *testing.T) {
+	m := New(quad(), 1e-4)
+	RecomputeNormals(m, AngleWeighted)
+	want := stl.Vertex{X: 0, Y: 0, Z: 1}
+	for i, n := range m.Normals {
+		if n != want {
+			t.Errorf("Normal %d = %+v, want %+v", i, n, want)
+		}
+	}
+}
+
+// tetrahedron returns a closed, consistently wound, four-triangle solid,
+// so every edge is shared by exactly two triangles.
+func tetrahedron() []*stl.Facet {
+	a := stl.Vertex{X: 0, Y: 0, Z: 0}
+	b := stl.Vertex{X: 1, Y: 0, Z: 0}
+	c := stl.Vertex{X: 0, Y: 1, Z: 0}
+	d := stl.Vertex{X: 0, Y: 0, Z: 1}
+	return []*stl.Facet{
+		{Vertices: [3]stl.Vertex{a, c, b}, Valid: true},
+		{Vertices: [3]stl.Vertex{a, b, d}, Valid: true},
+		{Vertices: [3]stl.Vertex{b, c, d}, Valid: true},
+		{Vertices: [3]stl.Vertex{c, a, d}, Valid: true},
+	}
+}
+
+func TestValidateCleanSolid(t *testing.T) {
+	m := New(tetrahedron(), 1e-4)
+	defects := Validate(m)
+	if len(defects) != 0 {
+		t.Fatalf("Expected no defects on a closed, consistently wound solid, got %+v", defects)
+	}
+}
+
+func TestValidateOpenMeshBoundary(t *testing.T) {
+	m := New(quad(), 1e-4)
+	defects := Validate(m)
+	for _, d := range defects {
+		if d.Kind != NonManifoldEdge {
+			t.Errorf("Unexpected defect on an open quad: %+v", d)
+		}
+	}
+	if len(defects) == 0 {
+		t.Fatalf("Expected the quad's boundary edges to report as non-manifold")
+	}
+}
+
+func TestValidateDegenerateTriangle(t *testing.T) {
+	a := stl.Vertex{X: 0, Y: 0, Z: 0}
+	b := stl.Vertex{X: 1, Y: 0, Z: 0}
+	facets := []*stl.Facet{{Vertices: [3]stl.Vertex{a, a, b}, Valid: true}}
+	m := New(facets, 1e-4)
+	defects := Validate(m)
+	found := false
+	for _, d := range defects {
+		if d.Kind == DegenerateTriangle {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("Expected a DegenerateTriangle defect, got %+v", defects)
+	}
+}
+
+func TestValidateUnreferencedVertex(t *testing.T) {
+	m := New(quad(), 1e-4)
+	m.Vertices = append(m.Vertices, stl.Vertex{X: 99, Y: 99, Z: 99})
+	defects := Validate(m)
+	found := false
+	for _, d := range defects {
+		if d.Kind == UnreferencedVertex && d.Index == uint32(len(m.Vertices)-1) {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("Expected an UnreferencedVertex defect for the appended vertex, got %+v", defects)
+	}
+}
+
+func TestValidateNonManifoldEdge(t *testing.T) {
+	a := stl.Vertex{X: 0, Y: 0, Z: 0}
+	b := stl.Vertex{X: 1, Y: 0, Z: 0}
+	c := stl.Vertex{X: 1, Y: 1, Z: 0}
+	d := stl.Vertex{X: 0, Y: 1, Z: 1}
+	facets := []*stl.Facet{
+		{Vertices: [3]stl.Vertex{a, b, c}, Valid: true},
+		{Vertices: [3]stl.Vertex{a, b, d}, Valid: true},
+	}
+	m := New(facets, 1e-4)
+	defects := Validate(m)
+	found := false
+	for _, d := range defects {
+		if d.Kind == NonManifoldEdge {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("Expected a NonManifoldEdge defect, got %+v", defects)
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright 2013 Silas Snider. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package mesh
+
+import (
+	"math"
+
+	"github.com/SniderLabs/stl"
+)
+
+//NormalMethod selects how RecomputeNormals derives per-vertex normals.
+type NormalMethod int
+
+const (
+	// FlatNormals assigns each vertex the face normal of the last
+	// non-degenerate triangle that touches it (the zero vector if there
+	// is none), so shared edges appear faceted.
+	FlatNormals NormalMethod = iota
+	// AngleWeighted assigns each vertex the average of its incident
+	// triangles' face normals, weighted by the angle each triangle
+	// subtends at that vertex, for smooth shading.
+	AngleWeighted
+)
+
+func sub(a, b stl.Vertex) stl.Vertex {
+	return stl.Vertex{X: a.X - b.X, Y: a.Y - b.Y, Z: a.Z - b.Z}
+}
+
+func cross(a, b stl.Vertex) stl.Vertex {
+	return stl.Vertex{
+		X: a.Y*b.Z - a.Z*b.Y,
+		Y: a.Z*b.X - a.X*b.Z,
+		Z: a.X*b.Y - a.Y*b.X,
+	}
+}
+
+func dot(a, b stl.Vertex) float32 {
+	return a.X*b.X + a.Y*b.Y + a.Z*b.Z
+}
+
+func length(v stl.Vertex) float32 {
+	return float32(math.Sqrt(float64(dot(v, v))))
+}
+
+func normalize(v stl.Vertex) stl.Vertex {
+	l := length(v)
+	if l == 0 {
+		return v
+	}
+	return stl.Vertex{X: v.X / l, Y: v.Y / l, Z: v.Z / l}
+}
+
+// faceNormal returns the normalized cross-product normal of triangle
+// a-b-c, and false if the triangle is degenerate (zero area).
+func faceNormal(a, b, c stl.Vertex) (stl.Vertex, bool) {
+	n := cross(sub(b, a), sub(c, a))
+	if length(n) == 0 {
+		return stl.Vertex{}, false
+	}
+	return normalize(n), true
+}
+
+// angle returns the angle in radians at vertex b of triangle a-b-c.
+func angle(a, b, c stl.Vertex) float32 {
+	u, v := sub(a, b), sub(c, b)
+	lu, lv := length(u), length(v)
+	if lu == 0 || lv == 0 {
+		return 0
+	}
+	cosTheta := dot(u, v) / (lu * lv)
+	switch {
+	case cosTheta > 1:
+		cosTheta = 1
+	case cosTheta < -1:
+		cosTheta = -1
+	}
+	return float32(math.Acos(float64(cosTheta)))
+}
+
+// RecomputeNormals fills in m.Normals, one entry per vertex in m.Vertices,
+// using the given method.
+func RecomputeNormals(m *Mesh, method NormalMethod) {
+	m.Normals = make([]stl.Vertex, len(m.Vertices))
+	if method == FlatNormals {
+		recomputeFlatNormals(m)
+		return
+	}
+	recomputeAngleWeightedNormals(m)
+}
+
+func recomputeFlatNormals(m *Mesh) {
+	for t := 0; t+2 < len(m.Indices); t += 3 {
+		ia, ib, ic := m.Indices[t], m.Indices[t+1], m.Indices[t+2]
+		n, ok := faceNormal(m.Vertices[ia], m.Vertices[ib], m.Vertices[ic])
+		if !ok {
+			continue
+		}
+		m.Normals[ia], m.Normals[ib], m.Normals[ic] = n, n, n
+	}
+}
+
+func recomputeAngleWeightedNormals(m *Mesh) {
+	accum := make([]stl.Vertex, len(m.Vertices))
+	for t := 0; t+2 < len(m.Indices); t += 3 {
+		idx := [3]uint32{m.Indices[t], m.Indices[t+1], m.Indices[t+2]}
+		verts := [3]stl.Vertex{m.Vertices[idx[0]], m.Vertices[idx[1]], m.Vertices[idx[2]]}
+		n, ok := faceNormal(verts[0], verts[1], verts[2])
+		if !ok {
+			continue
+		}
+		for i, vi := range idx {
+			w := angle(verts[(i+2)%3], verts[i], verts[(i+1)%3])
+			accum[vi] = stl.Vertex{
+				X: accum[vi].X + n.X*w,
+				Y: accum[vi].Y + n.Y*w,
+				Z: accum[vi].Z + n.Z*w,
+			}
+		}
+	}
+	for i, v := range accum {
+		m.Normals[i] = normalize(v)
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright 2013 Silas Snider. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package mesh
+
+//DefectKind categorizes a problem reported by Validate.
+type DefectKind int
+
+const (
+	// NonManifoldEdge marks an edge shared by a triangle count other
+	// than exactly 2.
+	NonManifoldEdge DefectKind = iota
+	// DegenerateTriangle marks a triangle with zero area.
+	DegenerateTriangle
+	// InvertedTriangle marks a triangle whose winding disagrees with a
+	// neighbor across a shared edge.
+	InvertedTriangle
+	// UnreferencedVertex marks a vertex with no incident triangle.
+	UnreferencedVertex
+)
+
+//Defect describes one problem Validate found in a Mesh.
+type Defect struct {
+	Kind DefectKind
+	// Index is the triangle index for DegenerateTriangle,
+	// InvertedTriangle and NonManifoldEdge (one Defect per triangle
+	// incident to the edge), or the vertex index for UnreferencedVertex.
+	Index uint32
+}
+
+type edge struct {
+	a, b uint32
+}
+
+func makeEdge(a, b uint32) edge {
+	if a > b {
+		a, b = b, a
+	}
+	return edge{a, b}
+}
+
+type directedEdge struct {
+	a, b uint32
+}
+
+// Validate reports non-manifold edges, degenerate or inverted triangles,
+// and unreferenced vertices in m.
+func Validate(m *Mesh) []Defect {
+	var defects []Defect
+
+	referenced := make([]bool, len(m.Vertices))
+	triangles := make(map[edge][]int)
+	directions := make(map[directedEdge]int)
+
+	triangle := 0
+	for t := 0; t+2 < len(m.Indices); t += 3 {
+		ia, ib, ic := m.Indices[t], m.Indices[t+1], m.Indices[t+2]
+		referenced[ia], referenced[ib], referenced[ic] = true, true, true
+
+		if _, ok := faceNormal(m.Vertices[ia], m.Vertices[ib], m.Vertices[ic]); !ok {
+			defects = append(defects, Defect{Kind: DegenerateTriangle, Index: uint32(triangle)})
+		}
+
+		corners := [3][2]uint32{{ia, ib}, {ib, ic}, {ic, ia}}
+		for _, c := range corners {
+			e := makeEdge(c[0], c[1])
+			triangles[e] = append(triangles[e], triangle)
+			directions[directedEdge{c[0], c[1]}]++
+		}
+		triangle++
+	}
+
+	for e, tris := range triangles {
+		if len(tris) != 2 {
+			for _, tri := range tris {
+				defects = append(defects, Defect{Kind: NonManifoldEdge, Index: uint32(tri)})
+			}
+			continue
+		}
+		// A consistently wound manifold mesh traverses any shared edge in
+		// opposite directions from its two triangles; if both triangles
+		// use the same direction, one of them is wound inconsistently.
+		if directions[directedEdge{e.a, e.b}] == 2 || directions[directedEdge{e.b, e.a}] == 2 {
+			for _, tri := range tris {
+				defects = append(defects, Defect{Kind: InvertedTriangle, Index: uint32(tri)})
+			}
+		}
+	}
+
+	for i, ref := range referenced {
+		if !ref {
+			defects = append(defects, Defect{Kind: UnreferencedVertex, Index: uint32(i)})
+		}
+	}
+
+	return defects
+}
@@ -30,6 +30,18 @@ type Facet struct {
 	Vertices [3]Vertex
 	Valid    bool
 	Normal   Vertex
+	// Attribute is the binary format's 2-byte per-facet "attribute byte
+	// count" slot. STL itself ignores it, but extensions such as the
+	// VisCAM/SolidView RGB15 color encoding store data there.
+	Attribute uint16
+}
+
+//Solid is one named "solid ... endsolid" block from an ASCII STL file.
+//Real-world slicers emit several of these back to back in a single file
+//when exporting an assembly.
+type Solid struct {
+	Name   string
+	Facets []*Facet
 }
 
 //LexerError represents an error generated by the lexer.
@@ -255,6 +267,7 @@ func asciiFacet(l *lexer) stateFn {
 
 func asciiFileHeader(l *lexer) stateFn {
 	l.acceptRun(spaces)
+	l.ignore()
 	l.acceptRun(ident)
 	c := string(l.input[l.start:l.pos])
 	if c == "facet" {
@@ -267,17 +280,39 @@ func asciiFileHeader(l *lexer) stateFn {
 
 // parseASCII knows how to parse ASCII STL files.
 func parseASCII(stl []byte) ([]*Facet, error) {
+	_, facets, _, err := parseASCIISolid(stl)
+	return facets, err
+}
+
+// parseASCIISolid parses a single "solid ... endsolid" block starting
+// right after the leading "solid" keyword, and returns the block's mesh
+// name, its facets, and whatever input follows the "endsolid" keyword
+// (which, for a multi-solid file, starts with the next "solid" block).
+func parseASCIISolid(block []byte) (string, []*Facet, []byte, error) {
 	var facets []*Facet
-	l := lex("ASCII STL", stl, asciiFileHeader)
+	l := lex("ASCII STL", block, asciiFileHeader)
 	f := l.nextFacet()
 	for f != nil && f.Valid {
 		facets = append(facets, f)
 		f = l.nextFacet()
 	}
 	if l.error != "" {
-		return nil, LexerError{e: l.error}
+		return "", nil, nil, LexerError{e: l.error}
 	}
-	return facets, nil
+	return l.meshName, facets, l.input[l.pos:], nil
+}
+
+// skipEndsolidName skips the optional mesh name (and surrounding
+// whitespace) that follows an "endsolid" keyword on the same line,
+// stopping at whatever comes next: either a following "solid" block or
+// the end of the file.
+func skipEndsolidName(b []byte) []byte {
+	b = bytes.TrimLeft(b, " \t")
+	i := 0
+	for i < len(b) && strings.IndexByte(ident, b[i]) >= 0 {
+		i++
+	}
+	return bytes.TrimLeft(b[i:], spaces)
 }
 
 // parseBinary knows how to parse binary STL files.
@@ -304,15 +339,16 @@ func parseBinary(stl []byte) ([]*Facet, error) {
 	facets := make([]*Facet, numTriangles)
 	for i := uint32(0); i < numTriangles; i++ {
 		var vs [3]Vertex
-		var normal Vertex
+		var ns [3]float32
 		//Read the normal
 		for j := 0; j < 3; j++ {
 			err = binary.Read(triangles, binary.LittleEndian, &t)
 			if err != nil {
 				return nil, err
 			}
-			normal = Vertex{X: ps[0], Y: ps[1], Z: ps[2]}
+			ns[j] = t
 		}
+		normal := Vertex{X: ns[0], Y: ns[1], Z: ns[2]}
 		//Read the vertices
 		for j := 0; j < 3; j++ {
 			for k := 0; k < 3; k++ {
@@ -323,15 +359,16 @@ func parseBinary(stl []byte) ([]*Facet, error) {
 			}
 			vs[j] = Vertex{X: ps[0], Y: ps[1], Z: ps[2]}
 		}
-		facets[i] = &Facet{
-			Vertices: vs,
-			Normal:   normal,
-			Valid:    true,
-		}
 		err = binary.Read(triangles, binary.LittleEndian, &ut)
 		if err != nil {
 			return nil, err
 		}
+		facets[i] = &Facet{
+			Vertices:  vs,
+			Normal:    normal,
+			Valid:     true,
+			Attribute: ut,
+		}
 	}
 
 	return facets, nil
@@ -347,20 +384,77 @@ func ParseSTL(stlPath string) ([]*Facet, error) {
 }
 
 // ParseSTLBytes returns the list of Facets that corresponds to the STL bytes passed in.
+// For a multi-solid ASCII file, it flattens every Solid's Facets into one slice, in order;
+// use ParseSolids to keep them separate.
 func ParseSTLBytes(stl []byte) ([]*Facet, error) {
-	if len(stl) < 5 {
+	solids, err := ParseSolids(stl)
+	if err != nil {
+		return nil, err
+	}
+	var facets []*Facet
+	for _, s := range solids {
+		facets = append(facets, s.Facets...)
+	}
+	return facets, nil
+}
+
+// ParseSolids returns every Solid in the STL bytes passed in. A binary
+// file, or an ASCII file with a single "solid ... endsolid" block, comes
+// back as a single Solid; an ASCII file with several consecutive blocks
+// (as emitted by slicers for assemblies) comes back as one Solid per block.
+func ParseSolids(stl []byte) ([]Solid, error) {
+	if len(stl) < solidLen {
 		return nil, errors.New("STL file too small (<5 bytes)")
 	}
-	if string(stl[0:5]) == "solid" {
-		facets, err := parseASCII(stl[5:])
+	if string(stl[:solidLen]) != "solid" {
+		facets, err := parseBinary(stl)
 		if err != nil {
-			facets, err2 := parseBinary(stl)
-			if err2 != nil {
-				return nil, err
-			}
-			return facets, nil
+			return nil, err
+		}
+		return []Solid{{Facets: facets}}, nil
+	}
+
+	solids, err := scanASCIISolids(stl[solidLen:])
+	if err != nil {
+		if len(solids) > 0 {
+			// At least one solid block parsed cleanly, so this is a real
+			// ASCII syntax error, not a binary misdetection.
+			return nil, err
+		}
+		// The "solid" keyword can also legally start a binary file's
+		// 80-byte header (see TestNonStandardBinaryParse).
+		facets, err2 := parseBinary(stl)
+		if err2 != nil {
+			return nil, err
+		}
+		return []Solid{{Facets: facets}}, nil
+	}
+	return solids, nil
+}
+
+// scanASCIISolids parses one or more consecutive "solid ... endsolid"
+// blocks out of rest, which must start right after the first block's
+// leading "solid" keyword has already been stripped. On error it still
+// returns whatever solids parsed before the failure, so callers can tell
+// "failed on the very first block" (len(solids) == 0, possibly a
+// misdetected binary file) apart from "failed partway through a real
+// multi-solid stream".
+func scanASCIISolids(rest []byte) ([]Solid, error) {
+	var solids []Solid
+	for {
+		name, facets, remainder, err := parseASCIISolid(rest)
+		if err != nil {
+			return solids, err
+		}
+		solids = append(solids, Solid{Name: name, Facets: facets})
+
+		remainder = skipEndsolidName(remainder)
+		if len(remainder) == 0 {
+			return solids, nil
+		}
+		if len(remainder) < solidLen || string(remainder[:solidLen]) != "solid" {
+			return solids, errors.New("Expected 'solid' keyword starting the next block.")
 		}
-		return facets, nil
+		rest = remainder[solidLen:]
 	}
-	return parseBinary(stl)
 }
@@ -0,0 +1,46 @@
+// Copyright 2013 Silas Snider. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package stl
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+)
+
+// WriteASCII writes facets to w as a single ASCII "solid name ...
+// endsolid name" block, in the same layout the lexer accepts, so the
+// result parses cleanly back through ParseSTLBytes.
+func WriteASCII(w io.Writer, name string, facets []*Facet) error {
+	enc := NewEncoder(w, ASCIIFormat, name)
+	for _, f := range facets {
+		if err := enc.Encode(f); err != nil {
+			return err
+		}
+	}
+	return enc.Close()
+}
+
+// WriteBinary writes facets to w as a binary STL file with the given
+// 80-byte header, followed by the triangle count and one 50-byte record
+// per facet. Each record's attribute byte count is taken from the
+// corresponding Facet's Attribute field.
+func WriteBinary(w io.Writer, header [80]byte, facets []*Facet) error {
+	bw := bufio.NewWriter(w)
+	if _, err := bw.Write(header[:]); err != nil {
+		return err
+	}
+	var countBuf [4]byte
+	binary.LittleEndian.PutUint32(countBuf[:], uint32(len(facets)))
+	if _, err := bw.Write(countBuf[:]); err != nil {
+		return err
+	}
+	for _, f := range facets {
+		if _, err := bw.Write(binaryRecordBytes(f)); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
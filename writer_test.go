@@ -0,0 +1,130 @@
+// Copyright 2013 Silas Snider. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package stl
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteASCIIRoundTrip(t *testing.T) {
+	facets := []*Facet{
+		{
+			Normal:   Vertex{X: 1, Y: 0, Z: 0},
+			Vertices: [3]Vertex{{X: -2, Y: 0.5, Z: -0.5}, {X: -2, Y: 1.5, Z: -0.5}, {X: -2, Y: 1.5, Z: 0.5}},
+			Valid:    true,
+		},
+	}
+	var buf bytes.Buffer
+	if err := WriteASCII(&buf, "roundtrip", facets); err != nil {
+		t.Fatalf("WriteASCII: %v", err)
+	}
+	got, err := ParseSTLBytes(buf.Bytes())
+	if err != nil {
+		t.Fatalf("ParseSTLBytes: %v", err)
+	}
+	if len(got) != 1 || got[0].Vertices != facets[0].Vertices || got[0].Normal != facets[0].Normal {
+		t.Fatalf("Incorrect round trip. Got %+v, expected %+v", got, facets)
+	}
+}
+
+func TestWriteBinaryRoundTrip(t *testing.T) {
+	facets := []*Facet{
+		{
+			Normal:    Vertex{X: 1, Y: 0, Z: 0},
+			Vertices:  [3]Vertex{{X: -2, Y: 0.5, Z: -0.5}, {X: -2, Y: 1.5, Z: -0.5}, {X: -2, Y: 1.5, Z: 0.5}},
+			Valid:     true,
+			Attribute: 0x1234,
+		},
+	}
+	var header [80]byte
+	copy(header[:], "roundtrip")
+	var buf bytes.Buffer
+	if err := WriteBinary(&buf, header, facets); err != nil {
+		t.Fatalf("WriteBinary: %v", err)
+	}
+
+	got := decodeAll(t, bytes.NewReader(buf.Bytes()))
+	if len(got) != 1 || got[0].Vertices != facets[0].Vertices || got[0].Normal != facets[0].Normal {
+		t.Fatalf("Incorrect round trip. Got %+v, expected %+v", got, facets)
+	}
+	if got[0].Attribute != facets[0].Attribute {
+		t.Fatalf("Incorrect attribute. Got %#x, expected %#x", got[0].Attribute, facets[0].Attribute)
+	}
+}
+
+func TestParseSolidsMultiSolid(t *testing.T) {
+	const input = `solid first
+  facet normal 1 0 0
+    outer loop
+      vertex -2 0.5 -0.5
+      vertex -2 1.5 -0.5
+      vertex -2 1.5 0.5
+    endloop
+  endfacet
+endsolid first
+solid second
+  facet normal 0 1 0
+    outer loop
+      vertex 0 0 0
+      vertex 1 0 0
+      vertex 0 1 0
+    endloop
+  endfacet
+endsolid second
+`
+	solids, err := ParseSolids([]byte(input))
+	if err != nil {
+		t.Fatalf("ParseSolids: %v", err)
+	}
+	if len(solids) != 2 {
+		t.Fatalf("Expected 2 solids, got %d", len(solids))
+	}
+	if solids[0].Name != "first" || len(solids[0].Facets) != 1 {
+		t.Fatalf("Incorrect first solid: %+v", solids[0])
+	}
+	if solids[1].Name != "second" || len(solids[1].Facets) != 1 {
+		t.Fatalf("Incorrect second solid: %+v", solids[1])
+	}
+
+	flattened, err := ParseSTLBytes([]byte(input))
+	if err != nil {
+		t.Fatalf("ParseSTLBytes: %v", err)
+	}
+	if len(flattened) != 2 {
+		t.Fatalf("Expected ParseSTLBytes to flatten to 2 facets, got %d", len(flattened))
+	}
+}
+
+func TestParseSolidsSecondBlockMalformed(t *testing.T) {
+	// The first block is well-formed ASCII; the second has a syntax error.
+	// A failure here must surface as the real parse error, not trigger a
+	// binary reinterpretation of the whole buffer.
+	const input = `solid first
+  facet normal 1 0 0
+    outer loop
+      vertex -2 0.5 -0.5
+      vertex -2 1.5 -0.5
+      vertex -2 1.5 0.5
+    endloop
+  endfacet
+endsolid first
+solid second
+  facet normal 0 1 0
+    outer loop
+      NOT_A_VERTEX_KEYWORD 0 0 0
+      vertex 1 0 0
+      vertex 0 1 0
+    endloop
+  endfacet
+endsolid second
+`
+	if _, err := ParseSolids([]byte(input)); err == nil {
+		t.Fatal("ParseSolids: expected an error, got nil")
+	}
+	if _, err := ParseSTLBytes([]byte(input)); err == nil {
+		t.Fatal("ParseSTLBytes: expected an error, got nil")
+	}
+}